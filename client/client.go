@@ -9,7 +9,8 @@ import (
 	"sync"
 
 	"github.com/google/uuid"
-	"github.com/ybbus/jsonrpc"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/client/rpc"
 )
 
 type User struct {
@@ -21,6 +22,8 @@ type User struct {
 var (
 	users = make(map[string]User)
 	mutex = &sync.Mutex{}
+
+	rpcClient = rpc.New("http://localhost:5001/rpc")
 )
 
 func main() {
@@ -66,21 +69,18 @@ func createUser(w http.ResponseWriter, r *http.Request) {
 	user.ID = uuid.New().String()
 	users[user.ID] = user
 
-	// JSON-RPC call to server
-	client := jsonrpc.NewClient("http://localhost:5001/json-rpc")
-	response, err := client.Call("createUser", user)
+	// Mirror the new user into the kv store over JSON-RPC, keyed by id.
+	payload, err := json.Marshal(user)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-
-	var result map[string]interface{}
-	if err := json.Unmarshal(response.Result.([]byte), &result); err != nil {
+	if err := rpcClient.Post(r.Context(), user.ID, string(payload)); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	json.NewEncoder(w).Encode(result)
+	json.NewEncoder(w).Encode(user)
 }
 
 func getAllUsers(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,164 @@
+// Package rpc is a strongly-typed Go client for the kv JSON-RPC service,
+// replacing ad-hoc jsonrpc.NewClient(...).Call(...) call sites with a
+// typed API plus retry/backoff on transient failures.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Client calls the kv service over HTTP POSTs to a server's /rpc
+// endpoint, retrying with backoff on the generic -32000 server error and
+// transient network failures. Other error codes are returned at once.
+type Client struct {
+	endpoint   string
+	httpClient *http.Client
+	nextID     uint64
+
+	// MaxRetries is how many additional attempts are made after the first
+	// one fails. RetryBase is the backoff before the first retry, doubling
+	// (with jitter) on each subsequent attempt.
+	MaxRetries int
+	RetryBase  time.Duration
+}
+
+// New builds a Client posting requests to endpoint (e.g.
+// "http://localhost:8080/rpc") using a shared http.Client.
+func New(endpoint string) *Client {
+	return &Client{
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		MaxRetries: 3,
+		RetryBase:  100 * time.Millisecond,
+	}
+}
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      int         `json:"id"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      int             `json:"id"`
+}
+
+// rpcError mirrors the server's JSON-RPC error envelope.
+type rpcError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// isRetryableCode reports whether code is the server's generic/transient
+// server error (-32000, e.g. a storage backend being unreachable). Other
+// codes in the JSON-RPC "server error" range (-32000 to -32099) are
+// non-transient domain errors, such as the kv service's NotFound and
+// Conflict, and must not be retried.
+func isRetryableCode(code int) bool {
+	return code == -32000
+}
+
+func (c *Client) nextRequestID() int {
+	return int(atomic.AddUint64(&c.nextID, 1))
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	d := c.RetryBase << (attempt - 1)
+	return d + time.Duration(rand.Int63n(int64(d)/2+1))
+}
+
+func (c *Client) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(rpcRequest{
+		JSONRPC: "2.0",
+		Method:  method,
+		Params:  params,
+		ID:      c.nextRequestID(),
+	})
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(c.backoff(attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		res, err := c.doRequest(ctx, body)
+		if err != nil {
+			lastErr = err
+			continue // transient network failure: retry
+		}
+		if res.Error != nil {
+			if !isRetryableCode(res.Error.Code) {
+				return res.Error
+			}
+			lastErr = res.Error
+			continue
+		}
+
+		if result == nil || len(res.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(res.Result, result)
+	}
+	return fmt.Errorf("rpc %s: giving up after %d attempts: %w", method, c.MaxRetries+1, lastErr)
+}
+
+func (c *Client) doRequest(ctx context.Context, body []byte) (*rpcResponse, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpRes, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpRes.Body.Close()
+
+	var res rpcResponse
+	if err := json.NewDecoder(httpRes.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (c *Client) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := c.call(ctx, "get", map[string]string{"key": key}, &value)
+	return value, err
+}
+
+func (c *Client) Post(ctx context.Context, key, value string) error {
+	return c.call(ctx, "post", map[string]string{"key": key, "value": value}, nil)
+}
+
+func (c *Client) Put(ctx context.Context, key, value string) error {
+	return c.call(ctx, "put", map[string]string{"key": key, "value": value}, nil)
+}
+
+func (c *Client) Delete(ctx context.Context, key string) error {
+	return c.call(ctx, "delete", map[string]string{"key": key}, nil)
+}
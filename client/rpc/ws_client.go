@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketClient multiplexes concurrent calls over a single websocket
+// connection, matching pending responses to their request by id.
+type WebSocketClient struct {
+	conn *websocket.Conn
+
+	writeMu sync.Mutex // serializes conn.WriteJSON; gorilla allows one writer at a time
+
+	mu       sync.Mutex
+	pending  map[int]chan rpcResponse
+	nextID   uint64
+	closed   bool
+	closeErr error
+}
+
+// DialWebSocket connects to a server's /rpc/ws endpoint and starts
+// demultiplexing responses in the background.
+func DialWebSocket(ctx context.Context, url string) (*WebSocketClient, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &WebSocketClient{
+		conn:    conn,
+		pending: make(map[int]chan rpcResponse),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *WebSocketClient) readLoop() {
+	for {
+		var res rpcResponse
+		if err := c.conn.ReadJSON(&res); err != nil {
+			c.failAllPending(fmt.Errorf("websocket read: %w", err))
+			return
+		}
+
+		c.mu.Lock()
+		ch, ok := c.pending[res.ID]
+		if ok {
+			delete(c.pending, res.ID)
+		}
+		c.mu.Unlock()
+
+		if ok {
+			ch <- res
+		}
+	}
+}
+
+// failAllPending delivers err to every in-flight call once the connection
+// is lost, so callers blocked in call don't hang forever.
+func (c *WebSocketClient) failAllPending(err error) {
+	c.mu.Lock()
+	c.closed = true
+	c.closeErr = err
+	pending := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, ch := range pending {
+		ch <- rpcResponse{Error: &rpcError{Code: -32000, Message: err.Error()}}
+	}
+}
+
+func (c *WebSocketClient) nextRequestID() int {
+	return int(atomic.AddUint64(&c.nextID, 1))
+}
+
+func (c *WebSocketClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	id := c.nextRequestID()
+	ch := make(chan rpcResponse, 1)
+
+	c.mu.Lock()
+	if c.closed {
+		err := c.closeErr
+		c.mu.Unlock()
+		return fmt.Errorf("websocket client closed: %w", err)
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	req := rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: id}
+
+	c.writeMu.Lock()
+	c.conn.SetWriteDeadline(time.Now().Add(wsClientWriteWait))
+	err := c.conn.WriteJSON(req)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return fmt.Errorf("websocket write: %w", err)
+	}
+
+	select {
+	case res := <-ch:
+		if res.Error != nil {
+			return res.Error
+		}
+		if result == nil || len(res.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(res.Result, result)
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+const wsClientWriteWait = 10 * time.Second
+
+func (c *WebSocketClient) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := c.call(ctx, "get", map[string]string{"key": key}, &value)
+	return value, err
+}
+
+func (c *WebSocketClient) Post(ctx context.Context, key, value string) error {
+	return c.call(ctx, "post", map[string]string{"key": key, "value": value}, nil)
+}
+
+func (c *WebSocketClient) Put(ctx context.Context, key, value string) error {
+	return c.call(ctx, "put", map[string]string{"key": key, "value": value}, nil)
+}
+
+func (c *WebSocketClient) Delete(ctx context.Context, key string) error {
+	return c.call(ctx, "delete", map[string]string{"key": key}, nil)
+}
+
+// Close closes the underlying websocket connection.
+func (c *WebSocketClient) Close() error {
+	return c.conn.Close()
+}
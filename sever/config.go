@@ -0,0 +1,90 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage/bolt"
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage/postgres"
+)
+
+// Config selects and configures the Storage backend StartServer serves
+// against. Values are normally populated via ConfigFromEnv.
+type Config struct {
+	// Backend is one of "memory" (default), "bolt", or "postgres".
+	Backend string
+
+	// BoltPath is the database file used by the bolt backend.
+	BoltPath string
+
+	// BoltNoSync disables fsync on every bolt write transaction, trading
+	// durability on a crash for write throughput.
+	BoltNoSync bool
+
+	// Postgres connection settings used by the postgres backend.
+	PGHost     string
+	PGPort     string
+	PGUser     string
+	PGPassword string
+	PGDatabase string
+	PGSSLMode  string
+
+	// RequestTimeout bounds how long a single storage call may take before
+	// its context is cancelled. Zero disables the timeout.
+	RequestTimeout time.Duration
+}
+
+// ConfigFromEnv builds a Config from environment variables:
+//
+//	KV_BACKEND    "memory" (default), "bolt", or "postgres"
+//	BOLT_PATH     bolt database file (default "data.db")
+//	BOLT_NO_SYNC  "true" to skip fsync on bolt writes (default "false")
+//	PG_HOST, PG_PORT, PG_USER, PG_PASSWORD, PG_DATABASE, PG_SSLMODE
+//	KV_REQUEST_TIMEOUT  Go duration string, e.g. "5s" (default "5s")
+func ConfigFromEnv() Config {
+	cfg := Config{
+		Backend:        envOr("KV_BACKEND", "memory"),
+		BoltPath:       envOr("BOLT_PATH", "data.db"),
+		BoltNoSync:     envOr("BOLT_NO_SYNC", "false") == "true",
+		PGHost:         envOr("PG_HOST", "localhost"),
+		PGPort:         envOr("PG_PORT", "5432"),
+		PGUser:         envOr("PG_USER", "postgres"),
+		PGPassword:     os.Getenv("PG_PASSWORD"),
+		PGDatabase:     envOr("PG_DATABASE", "postgres"),
+		PGSSLMode:      envOr("PG_SSLMODE", "disable"),
+		RequestTimeout: 5 * time.Second,
+	}
+
+	if raw := os.Getenv("KV_REQUEST_TIMEOUT"); raw != "" {
+		if d, err := time.ParseDuration(raw); err == nil {
+			cfg.RequestTimeout = d
+		}
+	}
+
+	return cfg
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// newStorage constructs the Storage backend selected by cfg.Backend.
+func (cfg Config) newStorage() (storage.Storage, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return storage.NewInMemoryStorage(), nil
+	case "bolt":
+		return bolt.New(cfg.BoltPath, cfg.BoltNoSync)
+	case "postgres":
+		dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			cfg.PGHost, cfg.PGPort, cfg.PGUser, cfg.PGPassword, cfg.PGDatabase, cfg.PGSSLMode)
+		return postgres.New(dsn)
+	default:
+		return nil, fmt.Errorf("unknown KV_BACKEND %q", cfg.Backend)
+	}
+}
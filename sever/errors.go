@@ -0,0 +1,71 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/ybbus/jsonrpc/v2"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
+)
+
+// Canonical JSON-RPC codes this server produces. -32000 to -32099 is the
+// spec's reserved "Server error" range. Within it, codeServerError (-32000)
+// is reserved for generic/transient failures (storage backend down, I/O
+// errors) that a caller can sensibly retry; codeNotFound and codeConflict
+// are non-transient domain errors and deliberately sit outside that single
+// retryable code so clients such as client/rpc can tell the two apart
+// without guessing (retrying a conflicting write or a missing key forever
+// is never useful).
+const (
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+	codeServerError    = -32000
+	codeNotFound       = -32010
+	codeConflict       = -32011
+)
+
+// ErrInvalidParam is wrapped by services that reject a decoded argument on
+// validation grounds (as opposed to decodeParams failing to decode it at
+// all, which already produces a -32602 error on its own).
+var ErrInvalidParam = errors.New("invalid param")
+
+// withData attaches contextual data (e.g. the offending key) to a sentinel
+// error so toRPCError can surface it in the response's "data" field.
+type withData struct {
+	err  error
+	data interface{}
+}
+
+func (w *withData) Error() string { return w.err.Error() }
+func (w *withData) Unwrap() error { return w.err }
+
+// WithData wraps err with data to surface in the JSON-RPC error's "data"
+// field once it reaches toRPCError.
+func WithData(err error, data interface{}) error {
+	return &withData{err: err, data: data}
+}
+
+// toRPCError maps a typed error onto a JSON-RPC error envelope.
+func toRPCError(err error) *jsonrpc.RPCError {
+	var data interface{}
+	var wd *withData
+	if errors.As(err, &wd) {
+		data = wd.data
+	}
+
+	var decodeErr *paramDecodeError
+	switch {
+	case errors.Is(err, errMethodNotFound):
+		return &jsonrpc.RPCError{Code: codeMethodNotFound, Message: "Method not found"}
+	case errors.As(err, &decodeErr):
+		return &jsonrpc.RPCError{Code: codeInvalidParams, Message: "Invalid params: " + decodeErr.Error(), Data: data}
+	case errors.Is(err, ErrInvalidParam):
+		return &jsonrpc.RPCError{Code: codeInvalidParams, Message: err.Error(), Data: data}
+	case errors.Is(err, storage.ErrNotFound):
+		return &jsonrpc.RPCError{Code: codeNotFound, Message: err.Error(), Data: data}
+	case errors.Is(err, storage.ErrConflict):
+		return &jsonrpc.RPCError{Code: codeConflict, Message: err.Error(), Data: data}
+	default:
+		return &jsonrpc.RPCError{Code: codeServerError, Message: err.Error(), Data: data}
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// Handler invokes a single decoded JSON-RPC call and returns its result or
+// an error. Storage/service errors should use the typed errors in
+// errors.go (or the sentinels in package storage) so toRPCError can map
+// them onto the right JSON-RPC code.
+type Handler func(ctx context.Context, method string, params json.RawMessage) (interface{}, error)
+
+// Middleware wraps a Handler to add cross-cutting behavior (logging, auth,
+// panic recovery, ...) around dispatch without touching the dispatcher
+// itself.
+type Middleware func(next Handler) Handler
+
+// recoverMiddleware turns a panic inside a registered method into an
+// error response instead of crashing the request goroutine.
+func recoverMiddleware(next Handler) Handler {
+	return func(ctx context.Context, method string, params json.RawMessage) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic in method %q: %v", method, r)
+			}
+		}()
+		return next(ctx, method, params)
+	}
+}
+
+// loggingMiddleware logs every dispatched call that returns an error.
+func loggingMiddleware(next Handler) Handler {
+	return func(ctx context.Context, method string, params json.RawMessage) (interface{}, error) {
+		result, err := next(ctx, method, params)
+		if err != nil {
+			log.Printf("rpc %s failed: %v", method, err)
+		}
+		return result, err
+	}
+}
+
+// Use appends middleware to the dispatch chain, applied in the order
+// given: the first middleware sees the call first and wraps everything
+// after it, down to the registry itself.
+func (s *JSONRPCServer) Use(mw ...Middleware) {
+	s.middlewares = append(s.middlewares, mw...)
+
+	h := Handler(s.registry.Call)
+	for i := len(s.middlewares) - 1; i >= 0; i-- {
+		h = s.middlewares[i](h)
+	}
+	s.handler = h
+}
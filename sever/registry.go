@@ -0,0 +1,171 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	ctxType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errType = reflect.TypeOf((*error)(nil)).Elem()
+
+	errMethodNotFound = errors.New("method not found")
+)
+
+// paramDecodeError marks a failure to decode a request's params into a
+// registered method's argument type, so callers can tell it apart from an
+// error raised by the method body itself.
+type paramDecodeError struct {
+	err error
+}
+
+func (e *paramDecodeError) Error() string { return e.err.Error() }
+func (e *paramDecodeError) Unwrap() error { return e.err }
+
+// registeredMethod is one exported receiver method matching
+// func(ctx context.Context, args *ArgT) (ResultT, error), reachable by a
+// "namespace_method" RPC name.
+type registeredMethod struct {
+	receiver reflect.Value
+	method   reflect.Method
+	argType  reflect.Type // *ArgT
+}
+
+// Registry maps "namespace_method" RPC names to reflected receiver
+// methods, in the style of go-ethereum's rpc package: services register
+// themselves once at startup and are exposed without the core handler
+// needing a case for every method.
+type Registry struct {
+	mu      sync.RWMutex
+	methods map[string]registeredMethod
+}
+
+func NewRegistry() *Registry {
+	return &Registry{methods: make(map[string]registeredMethod)}
+}
+
+// Register scans receiver's exported methods for the shape
+// func(ctx context.Context, args *ArgT) (ResultT, error) and exposes each
+// as "namespace_method", with the method name lowercased at the first
+// letter (Get -> get). It returns the RPC names that were registered.
+func (reg *Registry) Register(namespace string, receiver interface{}) []string {
+	rv := reflect.ValueOf(receiver)
+	rt := rv.Type()
+
+	var names []string
+	for i := 0; i < rt.NumMethod(); i++ {
+		m := rt.Method(i)
+		if !isRPCMethod(m.Func.Type()) {
+			continue
+		}
+
+		name := namespace + "_" + lowerFirst(m.Name)
+		reg.mu.Lock()
+		reg.methods[name] = registeredMethod{
+			receiver: rv,
+			method:   m,
+			argType:  m.Func.Type().In(2),
+		}
+		reg.mu.Unlock()
+		names = append(names, name)
+	}
+	return names
+}
+
+// Alias exposes an already-registered method under an additional name,
+// e.g. aliasing "kv_get" as "get" for backward compatibility. It reports
+// whether target was registered.
+func (reg *Registry) Alias(alias, target string) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	m, ok := reg.methods[target]
+	if !ok {
+		return false
+	}
+	reg.methods[alias] = m
+	return true
+}
+
+// isRPCMethod reports whether fn has the shape
+// func(receiver, context.Context, *ArgT) (ResultT, error).
+func isRPCMethod(fn reflect.Type) bool {
+	return fn.NumIn() == 3 &&
+		fn.In(1) == ctxType &&
+		fn.In(2).Kind() == reflect.Ptr &&
+		fn.NumOut() == 2 &&
+		fn.Out(1) == errType
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// Call decodes params into the argument type of the method registered
+// under name and invokes it, returning its result or an error describing
+// why it couldn't run (errMethodNotFound, a *paramDecodeError, or
+// whatever the method itself returned).
+func (reg *Registry) Call(ctx context.Context, name string, params json.RawMessage) (interface{}, error) {
+	reg.mu.RLock()
+	m, ok := reg.methods[name]
+	reg.mu.RUnlock()
+	if !ok {
+		return nil, errMethodNotFound
+	}
+
+	argPtr := reflect.New(m.argType.Elem())
+	if err := decodeParams(params, argPtr.Interface()); err != nil {
+		return nil, &paramDecodeError{err}
+	}
+
+	out := m.method.Func.Call([]reflect.Value{m.receiver, reflect.ValueOf(ctx), argPtr})
+	if errVal, _ := out[1].Interface().(error); errVal != nil {
+		return nil, errVal
+	}
+	return out[0].Interface(), nil
+}
+
+// decodeParams fills target (a pointer to a struct) from a raw JSON-RPC
+// params value, accepting both named ({"key": "k"}) and positional
+// (["k"]) forms without any unchecked type assertions.
+func decodeParams(params json.RawMessage, target interface{}) error {
+	trimmed := bytes.TrimSpace(params)
+	if len(trimmed) == 0 || string(trimmed) == "null" {
+		return nil
+	}
+
+	if trimmed[0] == '[' {
+		var arr []json.RawMessage
+		if err := json.Unmarshal(trimmed, &arr); err != nil {
+			return err
+		}
+		return decodePositionalParams(arr, target)
+	}
+
+	return json.Unmarshal(trimmed, target)
+}
+
+// decodePositionalParams maps a positional params array onto target's
+// fields in declaration order.
+func decodePositionalParams(params []json.RawMessage, target interface{}) error {
+	rv := reflect.ValueOf(target).Elem()
+	rt := rv.Type()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("positional params require a struct argument type")
+	}
+
+	for i := 0; i < rt.NumField() && i < len(params); i++ {
+		if err := json.Unmarshal(params[i], rv.Field(i).Addr().Interface()); err != nil {
+			return fmt.Errorf("field %s: %w", rt.Field(i).Name, err)
+		}
+	}
+	return nil
+}
@@ -1,137 +1,208 @@
 package server
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/ybbus/jsonrpc/v2"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
 )
 
-type Storage interface {
-	Get(key string) (string, error)
-	Post(key, value string) error
-	Put(key, value string) error
-	Delete(key string) error
-}
+// batchWorkerLimit bounds how many batch elements are dispatched concurrently.
+const batchWorkerLimit = 8
 
-type InMemoryStorage struct {
-	data map[string]string
-	mu   sync.RWMutex
+type JSONRPCServer struct {
+	storage        storage.Storage
+	registry       *Registry
+	middlewares    []Middleware
+	handler        Handler
+	requestTimeout time.Duration
 }
 
-func NewInMemoryStorage() *InMemoryStorage {
-	return &InMemoryStorage{
-		data: make(map[string]string),
-	}
-}
+func NewJSONRPCServer(store storage.Storage, requestTimeout time.Duration) *JSONRPCServer {
+	registry := NewRegistry()
+	registerKVService(registry, store)
 
-func (s *InMemoryStorage) Get(key string) (string, error) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	value, ok := s.data[key]
-	if !ok {
-		return "", fmt.Errorf("key not found")
+	s := &JSONRPCServer{
+		storage:        store,
+		registry:       registry,
+		requestTimeout: requestTimeout,
 	}
-	return value, nil
+	s.Use(recoverMiddleware, loggingMiddleware)
+	return s
 }
 
-func (s *InMemoryStorage) Post(key, value string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.data[key] = value
-	return nil
+// Register exposes receiver's RPC-shaped methods under namespace, so
+// additional services (auth, admin, metrics, ...) can be added without
+// touching HandleRequest.
+func (s *JSONRPCServer) Register(namespace string, receiver interface{}) []string {
+	return s.registry.Register(namespace, receiver)
 }
 
-func (s *InMemoryStorage) Put(key, value string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.data[key] = value
-	return nil
+// requestContext derives the context used for a single HTTP request's
+// storage calls, applying s.requestTimeout when one is configured.
+func (s *JSONRPCServer) requestContext(r *http.Request) (context.Context, context.CancelFunc) {
+	if s.requestTimeout <= 0 {
+		return r.Context(), func() {}
+	}
+	return context.WithTimeout(r.Context(), s.requestTimeout)
 }
 
-func (s *InMemoryStorage) Delete(key string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	delete(s.data, key)
-	return nil
+// rawRPCRequest decodes a JSON-RPC request while keeping ID as a pointer
+// (so a request whose "id" field is absent entirely, i.e. a notification,
+// can be told apart from one whose id is explicitly 0) and Params as raw
+// JSON (so decodeParams can decode it into a typed argument struct without
+// an intermediate, unchecked map[string]interface{}).
+type rawRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      *int            `json:"id,omitempty"`
 }
 
-type JSONRPCServer struct {
-	storage Storage
-}
+func (s *JSONRPCServer) HandleRequest(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := s.requestContext(r)
+	defer cancel()
 
-func NewJSONRPCServer(storage Storage) *JSONRPCServer {
-	return &JSONRPCServer{
-		storage: storage,
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(r.Body); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
 	}
-}
 
-func (s *JSONRPCServer) HandleRequest(w http.ResponseWriter, r *http.Request) {
-	var req jsonrpc.RPCRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if isBatchRequest(body.Bytes()) {
+		s.handleBatch(ctx, w, body.Bytes())
+		return
+	}
+
+	var raw rawRPCRequest
+	if err := json.Unmarshal(body.Bytes(), &raw); err != nil {
 		http.Error(w, "Invalid request", http.StatusBadRequest)
 		return
 	}
 
-	var res jsonrpc.RPCResponse
-	res.JSONRPC = "2.0"
-	res.ID = req.ID
+	res := s.handle(ctx, raw)
+	if raw.ID == nil {
+		// Notification: execute but send no body back.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
 
-	switch req.Method {
-	case "get":
-		key := req.Params.(map[string]interface{})["key"].(string)
-		value, err := s.storage.Get(key)
-		if err != nil {
-			res.Error = &jsonrpc.RPCError{Code: 1, Message: err.Error()}
-		} else {
-			res.Result = value
-		}
-	case "post":
-		params := req.Params.(map[string]interface{})
-		key := params["key"].(string)
-		value := params["value"].(string)
-		err := s.storage.Post(key, value)
-		if err != nil {
-			res.Error = &jsonrpc.RPCError{Code: 1, Message: err.Error()}
-		} else {
-			res.Result = "success"
+// isBatchRequest reports whether body encodes a JSON array rather than a
+// single JSON-RPC request object.
+func isBatchRequest(body []byte) bool {
+	trimmed := bytes.TrimLeft(body, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '['
+}
+
+func (s *JSONRPCServer) handleBatch(ctx context.Context, w http.ResponseWriter, body []byte) {
+	var rawBatch []json.RawMessage
+	if err := json.Unmarshal(body, &rawBatch); err != nil {
+		http.Error(w, "Invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if len(rawBatch) == 0 {
+		errRes := jsonrpc.RPCResponse{
+			JSONRPC: "2.0",
+			Error:   &jsonrpc.RPCError{Code: -32600, Message: "Invalid Request: empty batch"},
 		}
-	case "put":
-		params := req.Params.(map[string]interface{})
-		key := params["key"].(string)
-		value := params["value"].(string)
-		err := s.storage.Put(key, value)
-		if err != nil {
-			res.Error = &jsonrpc.RPCError{Code: 1, Message: err.Error()}
-		} else {
-			res.Result = "success"
+		if err := json.NewEncoder(w).Encode(errRes); err != nil {
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		}
-	case "delete":
-		key := req.Params.(map[string]interface{})["key"].(string)
-		err := s.storage.Delete(key)
-		if err != nil {
-			res.Error = &jsonrpc.RPCError{Code: 1, Message: err.Error()}
-		} else {
-			res.Result = "success"
+		return
+	}
+
+	responses := make([]*jsonrpc.RPCResponse, len(rawBatch))
+	sem := make(chan struct{}, batchWorkerLimit)
+	var wg sync.WaitGroup
+
+	for i, rawReq := range rawBatch {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, rawReq json.RawMessage) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			var raw rawRPCRequest
+			if err := json.Unmarshal(rawReq, &raw); err != nil {
+				responses[i] = &jsonrpc.RPCResponse{
+					JSONRPC: "2.0",
+					Error:   &jsonrpc.RPCError{Code: -32600, Message: "Invalid Request"},
+				}
+				return
+			}
+
+			res := s.handle(ctx, raw)
+			if raw.ID == nil {
+				// Notification: drop from the batch response.
+				return
+			}
+			responses[i] = res
+		}(i, rawReq)
+	}
+	wg.Wait()
+
+	ordered := make([]*jsonrpc.RPCResponse, 0, len(responses))
+	for _, res := range responses {
+		if res != nil {
+			ordered = append(ordered, res)
 		}
-	default:
-		res.Error = &jsonrpc.RPCError{Code: -32601, Message: "Method not found"}
 	}
 
-	if err := json.NewEncoder(w).Encode(res); err != nil {
+	if len(ordered) == 0 {
+		// The whole batch was notifications.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(ordered); err != nil {
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 	}
 }
 
-// StartServer starts the JSON-RPC server
-func StartServer(address string, storage Storage) {
-	server := NewJSONRPCServer(storage)
-	http.HandleFunc("/rpc", server.HandleRequest)
-	log.Printf("Starting server on %s...", address)
-	if err := http.ListenAndServe(address, nil); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+// handle dispatches a single decoded request through the middleware chain
+// and returns its response envelope, with JSONRPC/ID already populated.
+func (s *JSONRPCServer) handle(ctx context.Context, raw rawRPCRequest) *jsonrpc.RPCResponse {
+	res := &jsonrpc.RPCResponse{JSONRPC: "2.0"}
+	if raw.ID != nil {
+		res.ID = *raw.ID
+	}
+
+	result, err := s.handler(ctx, raw.Method, raw.Params)
+	if err != nil {
+		res.Error = toRPCError(err)
+		return res
 	}
+
+	res.Result = result
+	return res
+}
+
+// StartServer builds the storage backend selected by cfg and serves the
+// JSON-RPC API (HTTP and WebSocket) on address until ListenAndServe
+// returns.
+func StartServer(address string, cfg Config) error {
+	store, err := cfg.newStorage()
+	if err != nil {
+		return fmt.Errorf("create storage: %w", err)
+	}
+
+	server := NewJSONRPCServer(store, cfg.RequestTimeout)
+	http.HandleFunc("/rpc", server.HandleRequest)
+	http.HandleFunc("/rpc/ws", server.HandleWS)
+	log.Printf("Starting server on %s (backend=%s)...", address, cfg.Backend)
+	return http.ListenAndServe(address, nil)
 }
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"strings"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
+)
+
+// KVService exposes the original get/post/put/delete operations as
+// reflected RPC methods under the "kv" namespace.
+type KVService struct {
+	storage storage.Storage
+}
+
+func NewKVService(store storage.Storage) *KVService {
+	return &KVService{storage: store}
+}
+
+type KeyArgs struct {
+	Key string `json:"key"`
+}
+
+type KeyValueArgs struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (k *KVService) Get(ctx context.Context, args *KeyArgs) (string, error) {
+	if args.Key == "" {
+		return "", WithData(ErrInvalidParam, map[string]string{"field": "key"})
+	}
+	return k.storage.Get(ctx, args.Key)
+}
+
+func (k *KVService) Post(ctx context.Context, args *KeyValueArgs) (string, error) {
+	if args.Key == "" {
+		return "", WithData(ErrInvalidParam, map[string]string{"field": "key"})
+	}
+	if err := k.storage.Post(ctx, args.Key, args.Value); err != nil {
+		return "", err
+	}
+	return "success", nil
+}
+
+func (k *KVService) Put(ctx context.Context, args *KeyValueArgs) (string, error) {
+	if args.Key == "" {
+		return "", WithData(ErrInvalidParam, map[string]string{"field": "key"})
+	}
+	if err := k.storage.Put(ctx, args.Key, args.Value); err != nil {
+		return "", err
+	}
+	return "success", nil
+}
+
+func (k *KVService) Delete(ctx context.Context, args *KeyArgs) (string, error) {
+	if args.Key == "" {
+		return "", WithData(ErrInvalidParam, map[string]string{"field": "key"})
+	}
+	if err := k.storage.Delete(ctx, args.Key); err != nil {
+		return "", err
+	}
+	return "success", nil
+}
+
+// registerKVService exposes KVService under the "kv" namespace and keeps
+// the bare get/post/put/delete names working as aliases, so existing
+// clients don't have to change their method names.
+func registerKVService(registry *Registry, store storage.Storage) {
+	names := registry.Register("kv", NewKVService(store))
+	for _, name := range names {
+		registry.Alias(strings.TrimPrefix(name, "kv_"), name)
+	}
+}
@@ -0,0 +1,316 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/ybbus/jsonrpc/v2"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
+)
+
+const (
+	wsWriteWait  = 10 * time.Second
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = (wsPongWait * 9) / 10
+
+	// wsSendQueueSize bounds how many outbound messages (responses and
+	// subscription events) can be queued for a connection before new ones
+	// are dropped rather than blocking the reader.
+	wsSendQueueSize = 64
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsNotification is the "event" push sent to subscribers; unlike an
+// RPCResponse it is not tied to a request id.
+type wsNotification struct {
+	JSONRPC string       `json:"jsonrpc"`
+	Method  string       `json:"method"`
+	Params  wsEventParam `json:"params"`
+}
+
+type wsEventParam struct {
+	Subscription string `json:"subscription"`
+	Op           string `json:"op"`
+	Key          string `json:"key"`
+	Value        string `json:"value,omitempty"`
+}
+
+// wsConn dispatches JSON-RPC requests received over a single websocket
+// connection through the same handler pipeline as HandleRequest, and
+// additionally supports subscribe/unsubscribe for server-push key events.
+type wsConn struct {
+	server *JSONRPCServer
+	conn   *websocket.Conn
+	send   chan interface{}
+	ctx    context.Context
+
+	mu     sync.Mutex
+	subs   map[string]storage.CancelFunc
+	subSeq uint64
+}
+
+// HandleWS upgrades the connection to a websocket and serves JSON-RPC over
+// it, including subscribe/unsubscribe support, until the client disconnects
+// or the request context is cancelled.
+func (s *JSONRPCServer) HandleWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket upgrade failed: %v", err)
+		return
+	}
+
+	ctx := r.Context()
+	c := &wsConn{
+		server: s,
+		conn:   conn,
+		send:   make(chan interface{}, wsSendQueueSize),
+		ctx:    ctx,
+		subs:   make(map[string]storage.CancelFunc),
+	}
+
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	go c.writePump()
+	c.readPump()
+}
+
+func (c *wsConn) readPump() {
+	defer c.close()
+
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+		return nil
+	})
+
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.dispatch(data)
+	}
+}
+
+func (c *wsConn) writePump() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteJSON(msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *wsConn) close() {
+	c.conn.Close()
+
+	c.mu.Lock()
+	subs := c.subs
+	c.subs = nil
+	c.mu.Unlock()
+
+	for _, cancel := range subs {
+		cancel()
+	}
+}
+
+func (c *wsConn) dispatch(data []byte) {
+	if isBatchRequest(data) {
+		var rawBatch []json.RawMessage
+		if err := json.Unmarshal(data, &rawBatch); err != nil {
+			return
+		}
+		for _, rawReq := range rawBatch {
+			c.dispatchOne(rawReq)
+		}
+		return
+	}
+	c.dispatchOne(data)
+}
+
+func (c *wsConn) dispatchOne(data []byte) {
+	var raw rawRPCRequest
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	var res *jsonrpc.RPCResponse
+	switch raw.Method {
+	case "subscribe":
+		res = c.handleSubscribe(raw)
+	case "unsubscribe":
+		res = c.handleUnsubscribe(raw)
+	default:
+		ctx, cancel := c.callContext()
+		defer cancel()
+		res = c.server.handle(ctx, raw)
+	}
+
+	if raw.ID == nil {
+		// Notification: no response is sent back.
+		return
+	}
+
+	select {
+	case c.send <- res:
+	default:
+	}
+}
+
+func (c *wsConn) handleSubscribe(raw rawRPCRequest) *jsonrpc.RPCResponse {
+	res := newWSResponse(raw)
+
+	prefix, err := decodeSubscribeParams(raw.Params)
+	if err != nil {
+		res.Error = &jsonrpc.RPCError{Code: -32602, Message: err.Error()}
+		return res
+	}
+
+	events, cancel := c.server.storage.Watch(prefix)
+	id := c.nextSubscriptionID()
+
+	c.mu.Lock()
+	if c.subs == nil {
+		// Connection already closing.
+		c.mu.Unlock()
+		cancel()
+		res.Error = &jsonrpc.RPCError{Code: -32000, Message: "connection closing"}
+		return res
+	}
+	c.subs[id] = cancel
+	c.mu.Unlock()
+
+	go c.pumpEvents(id, events)
+
+	res.Result = id
+	return res
+}
+
+func (c *wsConn) handleUnsubscribe(raw rawRPCRequest) *jsonrpc.RPCResponse {
+	res := newWSResponse(raw)
+
+	id, err := decodeUnsubscribeParams(raw.Params)
+	if err != nil {
+		res.Error = &jsonrpc.RPCError{Code: -32602, Message: err.Error()}
+		return res
+	}
+
+	c.mu.Lock()
+	cancel, ok := c.subs[id]
+	if ok {
+		delete(c.subs, id)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		res.Error = &jsonrpc.RPCError{Code: -32602, Message: "unknown subscription id"}
+		return res
+	}
+
+	cancel()
+	res.Result = true
+	return res
+}
+
+func (c *wsConn) pumpEvents(id string, events <-chan storage.Event) {
+	for ev := range events {
+		notif := wsNotification{
+			JSONRPC: "2.0",
+			Method:  "event",
+			Params: wsEventParam{
+				Subscription: id,
+				Op:           ev.Op,
+				Key:          ev.Key,
+				Value:        ev.Value,
+			},
+		}
+		select {
+		case c.send <- notif:
+		default:
+			// Outbound queue is full; drop this notification rather than
+			// block the shared writer.
+		}
+	}
+}
+
+// callContext derives the context used for a single dispatched call,
+// applying the server's configured request timeout.
+func (c *wsConn) callContext() (context.Context, context.CancelFunc) {
+	if c.server.requestTimeout <= 0 {
+		return c.ctx, func() {}
+	}
+	return context.WithTimeout(c.ctx, c.server.requestTimeout)
+}
+
+func (c *wsConn) nextSubscriptionID() string {
+	return fmt.Sprintf("sub-%d", atomic.AddUint64(&c.subSeq, 1))
+}
+
+func newWSResponse(raw rawRPCRequest) *jsonrpc.RPCResponse {
+	res := &jsonrpc.RPCResponse{JSONRPC: "2.0"}
+	if raw.ID != nil {
+		res.ID = *raw.ID
+	}
+	return res
+}
+
+// decodeSubscribeParams reads subscribe's single "prefix" argument from
+// either a named ({"prefix": "k"}) or positional (["k"]) params value.
+func decodeSubscribeParams(params json.RawMessage) (string, error) {
+	var args struct {
+		Prefix string `json:"prefix"`
+	}
+	if err := decodeParams(params, &args); err != nil {
+		return "", fmt.Errorf("invalid params: %w", err)
+	}
+	return args.Prefix, nil
+}
+
+// decodeUnsubscribeParams reads unsubscribe's single "id" argument from
+// either a named ({"id": "sub-1"}) or positional (["sub-1"]) params value.
+func decodeUnsubscribeParams(params json.RawMessage) (string, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := decodeParams(params, &args); err != nil {
+		return "", fmt.Errorf("invalid params: %w", err)
+	}
+	if args.ID == "" {
+		return "", fmt.Errorf("missing id")
+	}
+	return args.ID, nil
+}
@@ -0,0 +1,110 @@
+// Package bolt implements storage.Storage on top of a local BoltDB file,
+// so data survives a process restart.
+package bolt
+
+import (
+	"context"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
+)
+
+var bucketName = []byte("kv")
+
+// Storage is a storage.Storage backed by a single bbolt bucket, with one
+// read/write transaction per operation.
+type Storage struct {
+	db     *bolt.DB
+	events *storage.EventHub
+}
+
+// New opens (creating if necessary) the bbolt database at path and ensures
+// the kv bucket exists. By default every write transaction is fsynced to
+// disk (bbolt's NoSync=false); pass noSync=true to skip the fsync for
+// higher throughput at the cost of durability on a crash.
+func New(path string, noSync bool) (*Storage, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open bolt db %q: %w", path, err)
+	}
+	db.NoSync = noSync
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create bucket: %w", err)
+	}
+
+	return &Storage{db: db, events: storage.NewEventHub()}, nil
+}
+
+// Close releases the underlying bbolt file handle.
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	var value string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(bucketName).Get([]byte(key))
+		if v == nil {
+			return storage.ErrNotFound
+		}
+		value = string(v)
+		return nil
+	})
+	return value, err
+}
+
+func (s *Storage) Post(ctx context.Context, key, value string) error {
+	if err := s.put(ctx, key, value); err != nil {
+		return err
+	}
+	s.events.Notify("post", key, value)
+	return nil
+}
+
+func (s *Storage) Put(ctx context.Context, key, value string) error {
+	if err := s.put(ctx, key, value); err != nil {
+		return err
+	}
+	s.events.Notify("put", key, value)
+	return nil
+}
+
+func (s *Storage) put(ctx context.Context, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(key), []byte(value))
+	})
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete([]byte(key))
+	})
+	if err != nil {
+		return err
+	}
+	s.events.Notify("delete", key, "")
+	return nil
+}
+
+// Watch subscribes to events for keys matching prefix.
+func (s *Storage) Watch(prefix string) (<-chan storage.Event, storage.CancelFunc) {
+	return s.events.Watch(prefix)
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"strings"
+	"sync"
+)
+
+// subscriberQueueSize bounds how many undelivered events a single
+// subscriber can have queued before the oldest is dropped to make room
+// for an "overflow" marker (see Notify).
+const subscriberQueueSize = 32
+
+type subscriber struct {
+	ch chan Event
+}
+
+// EventHub implements the subscribe/notify bookkeeping behind Storage.Watch
+// so every backend (memory, bolt, postgres, ...) can reuse the same
+// prefix-matching and back-pressure policy instead of reimplementing it.
+type EventHub struct {
+	mu          sync.RWMutex
+	subscribers map[string][]*subscriber
+}
+
+func NewEventHub() *EventHub {
+	return &EventHub{subscribers: make(map[string][]*subscriber)}
+}
+
+// Watch subscribes to events for keys matching prefix. The returned channel
+// is closed once cancel is called.
+func (h *EventHub) Watch(prefix string) (<-chan Event, CancelFunc) {
+	sub := &subscriber{ch: make(chan Event, subscriberQueueSize)}
+
+	h.mu.Lock()
+	h.subscribers[prefix] = append(h.subscribers[prefix], sub)
+	h.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			h.mu.Lock()
+			subs := h.subscribers[prefix]
+			for i, existing := range subs {
+				if existing == sub {
+					h.subscribers[prefix] = append(subs[:i], subs[i+1:]...)
+					break
+				}
+			}
+			h.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+
+	return sub.ch, cancel
+}
+
+// Notify fans out an event to every subscriber whose prefix matches key. A
+// subscriber whose queue is full has its oldest queued event dropped and
+// is sent an "overflow" event instead of the new one; the new event itself
+// is not delivered to that subscriber.
+func (h *EventHub) Notify(op, key, value string) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ev := Event{Op: op, Key: key, Value: value}
+	for prefix, subs := range h.subscribers {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		for _, sub := range subs {
+			select {
+			case sub.ch <- ev:
+			default:
+				select {
+				case <-sub.ch:
+				default:
+				}
+				select {
+				case sub.ch <- Event{Op: "overflow", Key: prefix}:
+				default:
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryStorage is a process-local Storage backed by a plain map. Data
+// does not survive a restart; use the bolt or postgres backends for that.
+type InMemoryStorage struct {
+	data   map[string]string
+	mu     sync.RWMutex
+	events *EventHub
+}
+
+func NewInMemoryStorage() *InMemoryStorage {
+	return &InMemoryStorage{
+		data:   make(map[string]string),
+		events: NewEventHub(),
+	}
+}
+
+func (s *InMemoryStorage) Get(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.data[key]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *InMemoryStorage) Post(ctx context.Context, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.data[key] = value
+	s.mu.Unlock()
+	s.events.Notify("post", key, value)
+	return nil
+}
+
+func (s *InMemoryStorage) Put(ctx context.Context, key, value string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.data[key] = value
+	s.mu.Unlock()
+	s.events.Notify("put", key, value)
+	return nil
+}
+
+func (s *InMemoryStorage) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.data, key)
+	s.mu.Unlock()
+	s.events.Notify("delete", key, "")
+	return nil
+}
+
+// Watch subscribes to events for keys matching prefix.
+func (s *InMemoryStorage) Watch(prefix string) (<-chan Event, CancelFunc) {
+	return s.events.Watch(prefix)
+}
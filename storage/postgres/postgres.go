@@ -0,0 +1,99 @@
+// Package postgres implements storage.Storage on top of a Postgres table,
+// using pgx directly (no ORM) to keep query shapes explicit.
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/abdurahmonrixsiboyev1998/Taqsir31/storage"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS kv (
+	key        TEXT PRIMARY KEY,
+	value      BYTEA NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+)`
+
+// Storage is a storage.Storage backed by a Postgres "kv" table, created
+// automatically on New if it doesn't already exist.
+type Storage struct {
+	pool   *pgxpool.Pool
+	events *storage.EventHub
+}
+
+// New connects to Postgres using dsn and runs the kv table automigration.
+func New(dsn string) (*Storage, error) {
+	pool, err := pgxpool.New(context.Background(), dsn)
+	if err != nil {
+		return nil, fmt.Errorf("connect to postgres: %w", err)
+	}
+
+	if _, err := pool.Exec(context.Background(), schema); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("automigrate kv table: %w", err)
+	}
+
+	return &Storage{pool: pool, events: storage.NewEventHub()}, nil
+}
+
+// Close releases the underlying connection pool.
+func (s *Storage) Close() {
+	s.pool.Close()
+}
+
+func (s *Storage) Get(ctx context.Context, key string) (string, error) {
+	var value []byte
+	err := s.pool.QueryRow(ctx, `SELECT value FROM kv WHERE key = $1`, key).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", storage.ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("get %q: %w", key, err)
+	}
+	return string(value), nil
+}
+
+func (s *Storage) Post(ctx context.Context, key, value string) error {
+	if err := s.upsert(ctx, key, value); err != nil {
+		return err
+	}
+	s.events.Notify("post", key, value)
+	return nil
+}
+
+func (s *Storage) Put(ctx context.Context, key, value string) error {
+	if err := s.upsert(ctx, key, value); err != nil {
+		return err
+	}
+	s.events.Notify("put", key, value)
+	return nil
+}
+
+func (s *Storage) upsert(ctx context.Context, key, value string) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO kv (key, value, updated_at) VALUES ($1, $2, now())
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = now()`,
+		key, []byte(value))
+	return err
+}
+
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	if _, err := s.pool.Exec(ctx, `DELETE FROM kv WHERE key = $1`, key); err != nil {
+		return err
+	}
+	s.events.Notify("delete", key, "")
+	return nil
+}
+
+// Watch subscribes to events for keys matching prefix. Events are only
+// published for mutations made through this process; they are not backed
+// by Postgres LISTEN/NOTIFY.
+func (s *Storage) Watch(prefix string) (<-chan storage.Event, storage.CancelFunc) {
+	return s.events.Watch(prefix)
+}
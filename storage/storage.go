@@ -0,0 +1,43 @@
+// Package storage defines the persistence contract used by the JSON-RPC
+// server, along with the in-memory reference implementation. Pluggable
+// backends live in sibling packages (bolt, postgres) and satisfy the same
+// Storage interface.
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned by Get when the key does not exist.
+var ErrNotFound = errors.New("key not found")
+
+// ErrConflict is returned by backends that support compare-and-swap style
+// writes when the current value doesn't match the caller's expectation.
+var ErrConflict = errors.New("conflict")
+
+// Event describes a single mutation published to watchers of a matching
+// key prefix.
+type Event struct {
+	Op    string // "post", "put", "delete", or "overflow"
+	Key   string
+	Value string
+}
+
+// CancelFunc stops a subscription started by Storage.Watch, releasing any
+// resources associated with it.
+type CancelFunc func()
+
+// Storage is the persistence contract the JSON-RPC server dispatches
+// get/post/put/delete through. Every method takes a context so backends
+// that hit a network or disk can be cancelled and timed out per call.
+type Storage interface {
+	Get(ctx context.Context, key string) (string, error)
+	Post(ctx context.Context, key, value string) error
+	Put(ctx context.Context, key, value string) error
+	Delete(ctx context.Context, key string) error
+	// Watch subscribes to Post/Put/Delete events for keys matching prefix,
+	// returning a channel of events and a CancelFunc to stop the
+	// subscription.
+	Watch(prefix string) (<-chan Event, CancelFunc)
+}